@@ -462,3 +462,51 @@ func ResetAmbient() error {
 	defer scwMu.Unlock()
 	return multisc.resetAmbient()
 }
+
+// RaiseAmbient is a superset of SetAmbient(true, vals...): it first
+// ensures vals are present in both the Permitted and Inheritable
+// flags of the current process' Set - priming them if necessary via
+// GetProc/SetProc - before raising them in the Ambient set. This is
+// the dance SetAmbient otherwise requires every caller to hand-roll
+// (the Go runtime's SysProcAttr.AmbientCaps support hit the same
+// requirement; see golang/go CL 156577), and it is safe to use during
+// early process startup. If any step fails, the process' Set is
+// restored to what GetProc reported on entry before the error is
+// returned.
+func RaiseAmbient(vals ...Value) error {
+	scwMu.Lock()
+	defer scwMu.Unlock()
+
+	old, err := GetPID(0)
+	if err != nil {
+		return err
+	}
+	snapshot, err := old.Dup()
+	if err != nil {
+		return err
+	}
+
+	if err := old.SetFlag(Permitted, true, vals...); err != nil {
+		return err
+	}
+	if err := old.SetFlag(Inheritable, true, vals...); err != nil {
+		return err
+	}
+	if err := multisc.setProc(old); err != nil {
+		return err
+	}
+	if err := multisc.setAmbient(true, vals...); err != nil {
+		multisc.setProc(snapshot)
+		return err
+	}
+	return nil
+}
+
+// LowerAmbient only touches the Ambient vector - it is the symmetric
+// counterpart to RaiseAmbient for callers that want to drop Ambient
+// bits without perturbing Permitted/Inheritable.
+func LowerAmbient(vals ...Value) error {
+	scwMu.Lock()
+	defer scwMu.Unlock()
+	return multisc.setAmbient(false, vals...)
+}
@@ -0,0 +1,294 @@
+package cap
+
+import (
+	"os"
+	"strings"
+)
+
+// Which is a bitmask over the five capability vectors a Capabilities
+// value can address in a single call. It exists to make this
+// package's API a drop-in replacement for the (now unmaintained)
+// syndtr/gocapability package's Capabilities interface, which several
+// widely used projects (Docker, runc, NoiseTorch) depend on.
+type Which uint
+
+// EFFECTIVE, PERMITTED and INHERITABLE address the matching Flag of
+// the wrapped *Set. BOUNDING and AMBIENT address the matching Vector
+// of the wrapped *IAB.
+const (
+	EFFECTIVE Which = 1 << iota
+	PERMITTED
+	INHERITABLE
+	BOUNDING
+	AMBIENT
+
+	CAPS = EFFECTIVE | PERMITTED | INHERITABLE
+)
+
+// Capabilities is a single-object facade over a *Set and an *IAB for
+// one process, combining Effective, Permitted, Inheritable, Bounding
+// and Ambient into the shape expected by code migrating off
+// syndtr/gocapability. Prefer the *Set/*IAB primitives directly for
+// new code; this interface exists for drop-in migration.
+type Capabilities interface {
+	// Get reports whether val is present in every vector named
+	// by which.
+	Get(which Which, val Value) bool
+
+	// Set raises vals in every vector named by which. The change
+	// is only visible to the kernel once Apply is called.
+	Set(which Which, vals ...Value)
+
+	// Unset lowers vals in every vector named by which. The
+	// change is only visible to the kernel once Apply is called.
+	Unset(which Which, vals ...Value)
+
+	// Clear lowers every Value in every vector named by which.
+	Clear(which Which)
+
+	// Load re-reads this process's Capabilities from the kernel,
+	// discarding any unapplied Set/Unset/Clear calls.
+	Load() error
+
+	// Apply pushes every vector named by which to the kernel, in
+	// the order SetProc, DropBound, then ResetAmbient+SetAmbient.
+	// If any step fails, Apply attempts to restore the
+	// Effective/Permitted/Inheritable and Ambient state the
+	// process had before the call (Bounding drops can never
+	// be undone by the kernel, so a failure after a partial
+	// DropBound leaves those bits dropped).
+	Apply(which Which) error
+
+	// String renders the Capabilities on a single line as
+	// name="val,val,..." for each of effective, permitted,
+	// inheritable, bounding and ambient, in that order, separated
+	// by spaces. Empty vectors are included as name="".
+	String() string
+}
+
+// capabilities is the concrete implementation of Capabilities.
+type capabilities struct {
+	pid int
+	set *Set
+	iab *IAB
+}
+
+// NewCapabilities returns a Capabilities wrapping the process
+// identified by pid (0 meaning the current process), initialized via
+// Load.
+func NewCapabilities(pid int) (Capabilities, error) {
+	c := &capabilities{pid: pid, iab: NewIAB()}
+	if err := c.Load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *capabilities) Load() error {
+	set, err := GetPID(c.pid)
+	if err != nil {
+		return err
+	}
+	c.set = set
+
+	if c.pid != 0 && c.pid != os.Getpid() {
+		// The Bounding and Ambient sets are only directly
+		// observable for the calling process.
+		c.iab = NewIAB()
+		return nil
+	}
+	iab := NewIAB()
+	for val := Value(0); val < MaxBits(); val++ {
+		if have, err := GetBound(val); err == nil && have {
+			iab.SetVector(Bound, true, val)
+		}
+		if have, err := GetAmbient(val); err == nil && have {
+			iab.SetVector(Amb, true, val)
+		}
+	}
+	c.iab = iab
+	return nil
+}
+
+// flagsOf returns the Flag values named by which that apply to a
+// *Set (i.e. excluding BOUNDING and AMBIENT).
+func flagsOf(which Which) []Flag {
+	var out []Flag
+	if which&EFFECTIVE != 0 {
+		out = append(out, Effective)
+	}
+	if which&PERMITTED != 0 {
+		out = append(out, Permitted)
+	}
+	if which&INHERITABLE != 0 {
+		out = append(out, Inheritable)
+	}
+	return out
+}
+
+func (c *capabilities) Get(which Which, val Value) bool {
+	for _, f := range flagsOf(which) {
+		have, err := c.set.GetFlag(f, val)
+		if err != nil || !have {
+			return false
+		}
+	}
+	if which&BOUNDING != 0 {
+		if have, err := c.iab.GetVector(Bound, val); err != nil || !have {
+			return false
+		}
+	}
+	if which&AMBIENT != 0 {
+		if have, err := c.iab.GetVector(Amb, val); err != nil || !have {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *capabilities) Set(which Which, vals ...Value) {
+	for _, f := range flagsOf(which) {
+		c.set.SetFlag(f, true, vals...)
+	}
+	if which&BOUNDING != 0 {
+		c.iab.SetVector(Bound, true, vals...)
+	}
+	if which&AMBIENT != 0 {
+		c.iab.SetVector(Amb, true, vals...)
+	}
+}
+
+func (c *capabilities) Unset(which Which, vals ...Value) {
+	for _, f := range flagsOf(which) {
+		c.set.SetFlag(f, false, vals...)
+	}
+	if which&BOUNDING != 0 {
+		c.iab.SetVector(Bound, false, vals...)
+	}
+	if which&AMBIENT != 0 {
+		c.iab.SetVector(Amb, false, vals...)
+	}
+}
+
+func (c *capabilities) Clear(which Which) {
+	for _, f := range flagsOf(which) {
+		c.set.ClearFlag(f)
+	}
+	if which&BOUNDING != 0 {
+		c.iab.Fill(Bound)
+	}
+	if which&AMBIENT != 0 {
+		c.iab.Fill(Amb)
+	}
+}
+
+func (c *capabilities) Apply(which Which) error {
+	scwMu.Lock()
+	defer scwMu.Unlock()
+
+	oldSet, err := GetPID(0)
+	if err != nil {
+		return err
+	}
+	var oldAmbient []Value
+	if which&AMBIENT != 0 {
+		for val := Value(0); val < MaxBits(); val++ {
+			if have, err := GetAmbient(val); err == nil && have {
+				oldAmbient = append(oldAmbient, val)
+			}
+		}
+	}
+	// restore is the rollback path for everything Apply can still
+	// undo: the process' Set as it was before this call, and (if AMBIENT is
+	// part of which) the Ambient bits that were raised before this
+	// call. It is best-effort - if the kernel is in a state where
+	// even this fails, there is nothing further Apply can do. It is
+	// also called on a BOUNDING failure (a failed DropBound), but
+	// can only undo the Set/Ambient side effects of that attempt:
+	// any Bounding Value already dropped before the failure stays
+	// dropped, since the kernel never allows one to be re-raised.
+	restore := func() {
+		multisc.setProc(oldSet)
+		if which&AMBIENT != 0 {
+			multisc.resetAmbient()
+			if len(oldAmbient) != 0 {
+				multisc.setAmbient(true, oldAmbient...)
+			}
+		}
+	}
+
+	if which&(EFFECTIVE|PERMITTED|INHERITABLE) != 0 {
+		if err := multisc.setProc(c.set); err != nil {
+			return err
+		}
+	}
+
+	if which&BOUNDING != 0 {
+		for val := Value(0); val < MaxBits(); val++ {
+			have, err := c.iab.GetVector(Bound, val)
+			if err != nil {
+				continue
+			}
+			if !have {
+				if present, err := GetBound(val); err == nil && present {
+					if err := multisc.dropBound(val); err != nil {
+						restore()
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if which&AMBIENT != 0 {
+		if err := multisc.resetAmbient(); err != nil {
+			restore()
+			return err
+		}
+		var raise []Value
+		for val := Value(0); val < MaxBits(); val++ {
+			if have, err := c.iab.GetVector(Amb, val); err == nil && have {
+				raise = append(raise, val)
+			}
+		}
+		if len(raise) != 0 {
+			if err := multisc.setAmbient(true, raise...); err != nil {
+				restore()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *capabilities) String() string {
+	var lines []string
+	flags := []struct {
+		name string
+		flag Flag
+	}{{"effective", Effective}, {"permitted", Permitted}, {"inheritable", Inheritable}}
+	for _, f := range flags {
+		var vals []string
+		for val := Value(0); val < MaxBits(); val++ {
+			if have, _ := c.set.GetFlag(f.flag, val); have {
+				vals = append(vals, val.String())
+			}
+		}
+		lines = append(lines, f.name+"=\""+strings.Join(vals, ",")+"\"")
+	}
+	vectors := []struct {
+		name string
+		vec  Vector
+	}{{"bounding", Bound}, {"ambient", Amb}}
+	for _, v := range vectors {
+		var vals []string
+		for val := Value(0); val < MaxBits(); val++ {
+			if have, _ := c.iab.GetVector(v.vec, val); have {
+				vals = append(vals, val.String())
+			}
+		}
+		lines = append(lines, v.name+"=\""+strings.Join(vals, ",")+"\"")
+	}
+	return strings.Join(lines, " ")
+}
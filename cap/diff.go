@@ -0,0 +1,167 @@
+package cap
+
+import "errors"
+
+// Diff reports how Set c differs from Set other: added holds the
+// Values present in other but not c, and removed holds the Values
+// present in c but not other, for each of Effective, Permitted and
+// Inheritable. This lets a caller inspect what a subsequent
+// other.SetProc() would actually change relative to c (for example,
+// relative to the Set currently installed via GetProc()) before
+// committing to it.
+func (c *Set) Diff(other *Set) (added, removed map[Flag][]Value, err error) {
+	if c == nil || other == nil || len(c.flat) == 0 || len(other.flat) == 0 {
+		return nil, nil, ErrBadSet
+	}
+	added = make(map[Flag][]Value)
+	removed = make(map[Flag][]Value)
+	for _, flag := range []Flag{Effective, Permitted, Inheritable} {
+		for val := Value(0); val < MaxBits(); val++ {
+			have, _ := c.GetFlag(flag, val)
+			want, _ := other.GetFlag(flag, val)
+			switch {
+			case want && !have:
+				added[flag] = append(added[flag], val)
+			case have && !want:
+				removed[flag] = append(removed[flag], val)
+			}
+		}
+	}
+	return added, removed, nil
+}
+
+// bitwise applies op to every word of every Flag of c and other,
+// returning the result as a new Set. c and other must have the same
+// number of words (true of any two Sets obtained from this package
+// on a single running kernel).
+func (c *Set) bitwise(other *Set, op func(a, b uint32) uint32) (*Set, error) {
+	if c == nil || other == nil || len(c.flat) == 0 || len(c.flat) != len(other.flat) {
+		return nil, ErrBadSet
+	}
+	c.mu.RLock()
+	other.mu.RLock()
+	defer c.mu.RUnlock()
+	defer other.mu.RUnlock()
+
+	n := NewSet()
+	for w := range c.flat {
+		for _, flag := range []Flag{Effective, Permitted, Inheritable} {
+			n.flat[w][flag] = op(c.flat[w][flag], other.flat[w][flag])
+		}
+	}
+	return n, nil
+}
+
+// Minus returns a new Set holding every Value of c that is not also
+// present in other, for each Flag. It is a pure function: neither c
+// nor other is modified.
+func (c *Set) Minus(other *Set) *Set {
+	n, err := c.bitwise(other, func(a, b uint32) uint32 { return a &^ b })
+	if err != nil {
+		return NewSet()
+	}
+	return n
+}
+
+// Union returns a new Set holding every Value present in either c or
+// other, for each Flag. It is a pure function: neither c nor other is
+// modified.
+func (c *Set) Union(other *Set) *Set {
+	n, err := c.bitwise(other, func(a, b uint32) uint32 { return a | b })
+	if err != nil {
+		return NewSet()
+	}
+	return n
+}
+
+// Transaction snapshots the calling process' capability state -
+// obtained from BeginProcTransaction() - so it can later be restored
+// with Rollback if a sequence of capability changes needs to be
+// undone.
+type Transaction struct {
+	snapshot *Set
+	ambient  []Value
+	bounding []Value
+	closed   bool
+}
+
+// Bounding returns the Bounding set Values present when
+// BeginProcTransaction took this snapshot. It is provided for
+// inspection/diffing only: the kernel never allows a dropped
+// Bounding Value to be re-raised, so unlike the Set and Ambient
+// snapshot, Rollback cannot use this to undo a DropBound call made
+// during the transaction.
+func (t *Transaction) Bounding() []Value {
+	return t.bounding
+}
+
+// BeginProcTransaction snapshots the current process' Set (via
+// GetProc), Ambient vector and Bounding set, returning a Transaction
+// that can later reverse any Set/Ambient changes made since with
+// Rollback, or simply be closed out with Commit once the caller is
+// satisfied with the new state.
+func BeginProcTransaction() (*Transaction, error) {
+	scwMu.Lock()
+	defer scwMu.Unlock()
+
+	set, err := GetPID(0)
+	if err != nil {
+		return nil, err
+	}
+	var ambient, bounding []Value
+	for val := Value(0); val < MaxBits(); val++ {
+		if have, err := GetAmbient(val); err == nil && have {
+			ambient = append(ambient, val)
+		}
+		if have, err := GetBound(val); err == nil && have {
+			bounding = append(bounding, val)
+		}
+	}
+	return &Transaction{snapshot: set, ambient: ambient, bounding: bounding}, nil
+}
+
+// ErrTransactionClosed indicates a Transaction's Commit or Rollback
+// was called more than once.
+var ErrTransactionClosed = errors.New("cap: transaction already closed")
+
+// Commit closes out the Transaction without touching the process'
+// current capability state: it is a bookkeeping no-op, present so
+// callers can make the success path of a capability change sequence
+// explicit and symmetric with Rollback.
+func (t *Transaction) Commit() error {
+	if t.closed {
+		return ErrTransactionClosed
+	}
+	t.closed = true
+	return nil
+}
+
+// Rollback restores the process' Set and Ambient vector to what they
+// were when BeginProcTransaction was called, re-raising any Ambient
+// bits that have since been cleared (Permitted/Inheritable are
+// restored first, by virtue of restoring the whole snapshotted Set,
+// so the kernel will permit re-raising them). The Bounding set is not
+// restored: the kernel never allows a dropped Bounding Value to be
+// re-raised, so any DropBound call made during the transaction is
+// permanent regardless of Rollback. Use Bounding() to inspect what
+// the set looked like at BeginProcTransaction, for diffing or
+// reporting purposes.
+func (t *Transaction) Rollback() error {
+	if t.closed {
+		return ErrTransactionClosed
+	}
+	t.closed = true
+
+	scwMu.Lock()
+	defer scwMu.Unlock()
+	if err := multisc.setProc(t.snapshot); err != nil {
+		return err
+	}
+	if err := multisc.resetAmbient(); err != nil {
+		return err
+	}
+	if len(t.ambient) == 0 {
+		return nil
+	}
+	return multisc.setAmbient(true, t.ambient...)
+}
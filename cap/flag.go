@@ -0,0 +1,65 @@
+package cap
+
+// GetFlag returns whether val is raised in the named Flag of Set c.
+func (c *Set) GetFlag(flag Flag, val Value) (bool, error) {
+	if c == nil || len(c.flat) == 0 {
+		return false, ErrBadSet
+	}
+	w := val >> 5
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if int(w) >= len(c.flat) {
+		return false, ErrBadSet
+	}
+	return c.flat[w][flag]&(1<<(val&31)) != 0, nil
+}
+
+// SetFlag raises (enable=true) or lowers (enable=false) each of vals
+// in the named Flag of Set c. No system call is made: the change
+// only becomes effective for the process once the Set is installed
+// with SetProc.
+func (c *Set) SetFlag(flag Flag, enable bool, val ...Value) error {
+	if c == nil || len(c.flat) == 0 {
+		return ErrBadSet
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range val {
+		w := v >> 5
+		if int(w) >= len(c.flat) {
+			return ErrBadSet
+		}
+		if enable {
+			c.flat[w][flag] |= 1 << (v & 31)
+		} else {
+			c.flat[w][flag] &^= 1 << (v & 31)
+		}
+	}
+	return nil
+}
+
+// ClearFlag lowers every Value in the named Flag of Set c.
+func (c *Set) ClearFlag(flag Flag) error {
+	if c == nil || len(c.flat) == 0 {
+		return ErrBadSet
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for w := range c.flat {
+		c.flat[w][flag] = 0
+	}
+	return nil
+}
+
+// Clear lowers every Value in every Flag of Set c.
+func (c *Set) Clear() error {
+	if c == nil || len(c.flat) == 0 {
+		return ErrBadSet
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for w := range c.flat {
+		c.flat[w] = data{}
+	}
+	return nil
+}
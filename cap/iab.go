@@ -0,0 +1,133 @@
+package cap
+
+import "sync"
+
+// Vector identifies one of the three capability vectors that, unlike
+// Effective/Permitted/Inheritable, are not carried in a single
+// CAPGET/CAPSET Set: the Bounding set, the Ambient set, and (here,
+// for symmetry) the Inheritable vector of a Set a caller intends to
+// apply alongside them.
+type Vector uint
+
+// Bound, Amb and Inh identify the three Vectors an IAB tracks.
+const (
+	Bound Vector = iota
+	Amb
+	Inh
+)
+
+// IAB ("Inheritable, Ambient, Bounding") is a convenience container
+// for the capability state that spans a Set: the process-wide
+// Bounding set, the process-wide Ambient set, and an Inheritable
+// vector intended to be applied to a Set before it is installed with
+// SetProc. It lets callers build up a desired Bounding/Ambient/
+// Inheritable configuration (for example, parsed from an OCI runtime
+// spec via ApplyCapList) before pushing it into the kernel with
+// Apply.
+type IAB struct {
+	mu  sync.RWMutex
+	vec [3][]uint32
+}
+
+// NewIAB returns an empty IAB: no bits raised in any Vector.
+func NewIAB() *IAB {
+	startUp.Do(multisc.cInit)
+	i := &IAB{}
+	for v := range i.vec {
+		i.vec[v] = make([]uint32, words)
+	}
+	return i
+}
+
+// validIABValue confirms val is addressable within the words
+// discovered for the running kernel.
+func validIABValue(val Value) error {
+	startUp.Do(multisc.cInit)
+	if uint(val) >= uint(words)*32 {
+		return ErrBadSet
+	}
+	return nil
+}
+
+// GetVector returns whether val is present in the Vector vec.
+func (i *IAB) GetVector(vec Vector, val Value) (bool, error) {
+	if err := validIABValue(val); err != nil {
+		return false, err
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.vec[vec][val>>5]&(1<<(val&31)) != 0, nil
+}
+
+// SetVector raises (enable=true) or lowers (enable=false) each of
+// vals in the Vector vec.
+func (i *IAB) SetVector(vec Vector, enable bool, vals ...Value) error {
+	for _, val := range vals {
+		if err := validIABValue(val); err != nil {
+			return err
+		}
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, val := range vals {
+		if enable {
+			i.vec[vec][val>>5] |= 1 << (val & 31)
+		} else {
+			i.vec[vec][val>>5] &^= 1 << (val & 31)
+		}
+	}
+	return nil
+}
+
+// Fill replaces the entire contents of Vector vec with vals (every
+// other bit in that Vector is cleared).
+func (i *IAB) Fill(vec Vector, vals ...Value) error {
+	i.mu.Lock()
+	for w := range i.vec[vec] {
+		i.vec[vec][w] = 0
+	}
+	i.mu.Unlock()
+	return i.SetVector(vec, true, vals...)
+}
+
+// Apply pushes the Bound and Amb Vectors of i into the kernel's
+// process-wide state for the current process: dropping every Bounding
+// bit the kernel still holds that is not present in i (bits already
+// dropped are left alone - the kernel never allows a dropped
+// Bounding bit to be re-raised), and resetting+raising the Ambient
+// set to exactly the bits i holds. The Inh Vector is not applied
+// directly by this call: fold it into a Set's Inheritable flag (see
+// (*Set).SetFlag) and apply that Set with SetProc.
+func (i *IAB) Apply() error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for val := Value(0); val < Value(len(i.vec[Bound]))*32; val++ {
+		have, err := i.vec[Bound][val>>5]&(1<<(val&31)) != 0, error(nil)
+		if err != nil {
+			return err
+		}
+		if have {
+			continue
+		}
+		if present, err := GetBound(val); err == nil && present {
+			if err := DropBound(val); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ResetAmbient(); err != nil {
+		return err
+	}
+	var raise []Value
+	for val := Value(0); val < Value(len(i.vec[Amb]))*32; val++ {
+		if i.vec[Amb][val>>5]&(1<<(val&31)) != 0 {
+			raise = append(raise, val)
+		}
+	}
+	if len(raise) == 0 {
+		return nil
+	}
+	return SetAmbient(true, raise...)
+}
@@ -0,0 +1,173 @@
+package cap
+
+import (
+	"runtime"
+	"unsafe"
+
+	"kernel.org/pub/linux/libs/security/libcap/cap/seccomp"
+)
+
+// defines from uapi/linux/prctl.h and uapi/linux/seccomp.h.
+const (
+	prSetNoNewPrivs = 38
+
+	seccompSetModeFilter = 1
+)
+
+// kernelSockFilter mirrors the kernel's "struct sock_filter"
+// (uapi/linux/filter.h): an 8-byte BPF instruction.
+type kernelSockFilter struct {
+	code uint16
+	jt   uint8
+	jf   uint8
+	k    uint32
+}
+
+// kernelSockFprog mirrors the kernel's "struct sock_fprog"
+// (uapi/linux/filter.h) used to hand a compiled BPF program to
+// SECCOMP_SET_MODE_FILTER. Deliberately no explicit padding field
+// between len and filter: the Go compiler already inserts exactly
+// the padding the kernel's C struct gets (pointer-aligned, so 6
+// bytes on 64-bit and 2 bytes on 32-bit), since both follow ordinary
+// alignment rules for a pointer-sized field. A hardcoded padding
+// size would be wrong on 32-bit architectures.
+type kernelSockFprog struct {
+	len    uint16
+	filter *kernelSockFilter
+}
+
+//go:uintptrescapes
+func (sc *syscaller) setSeccomp(prog *seccomp.Program) error {
+	codes, jts, jfs, ks, err := prog.Assemble()
+	if err != nil {
+		return err
+	}
+	filter := make([]kernelSockFilter, len(codes))
+	for i := range codes {
+		filter[i] = kernelSockFilter{code: codes[i], jt: jts[i], jf: jfs[i], k: ks[i]}
+	}
+	if _, err := sc.prctlwcall(prSetNoNewPrivs, 1, 0); err != nil {
+		return err
+	}
+	fprog := kernelSockFprog{len: uint16(len(filter)), filter: &filter[0]}
+	_, _, errno := sc.w3(sysSeccomp, seccompSetModeFilter, 0, uintptr(unsafe.Pointer(&fprog)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// SetProcWithSeccomp atomically (from the caller's point of view)
+// applies c as the capability Set of the calling OS thread and
+// installs prog as a seccomp BPF filter, in that order, so a
+// process never runs - even briefly - with the old Capabilities and
+// no syscall filter. Like SetProc, this serializes with other
+// writers via scwMu; unlike SetProc, both the Set and the filter
+// take effect only for the calling OS thread, so callers that need the
+// restriction to apply process-wide should either arrange for every
+// thread to call this, or prefer (*Launcher).SetSeccomp, which
+// installs the filter on the dedicated thread a Launcher callback
+// always runs on.
+func (c *Set) SetProcWithSeccomp(prog *seccomp.Program) error {
+	scwMu.Lock()
+	defer scwMu.Unlock()
+	if err := singlesc.setProc(c); err != nil {
+		return err
+	}
+	return singlesc.setSeccomp(prog)
+}
+
+// Launcher is a convenience mechanism for launching a new thread (or
+// process, via a callback that calls one of the Exec family) with a
+// pre-determined set of Capabilities, Ambient vector and, optionally,
+// a seccomp filter installed before the payload runs. Create one
+// with NewLauncher.
+type Launcher struct {
+	callback func(interface{}) error
+	set      *Set
+	seccomp  *seccomp.Program
+	ambient  []Value
+}
+
+// NewLauncher returns a Launcher that will invoke callback, with a
+// payload value of the caller's choosing, once any configured
+// capability and seccomp state has been applied to the launching
+// thread.
+func NewLauncher(callback func(interface{}) error) *Launcher {
+	return &Launcher{callback: callback}
+}
+
+// Callback replaces the function a Launcher invokes.
+func (l *Launcher) Callback(callback func(interface{}) error) {
+	l.callback = callback
+}
+
+// SetSet arranges for set to be applied, via SetProc (or
+// SetProcWithSeccomp, if a seccomp.Program is also configured), on
+// the thread a subsequent Launch runs its callback on. Pass nil to
+// launch without changing the thread's Capabilities.
+func (l *Launcher) SetSet(set *Set) {
+	l.set = set
+}
+
+// SetSeccomp arranges for prog to be installed as a seccomp BPF
+// filter on the thread a subsequent Launch runs its callback on, in
+// addition to whatever Set this Launcher is already configured to
+// apply. Pass nil to remove a previously configured filter.
+func (l *Launcher) SetSeccomp(prog *seccomp.Program) {
+	l.seccomp = prog
+}
+
+// SetAmbient arranges for vals to be raised in the Ambient set, via
+// RaiseAmbient, once this Launcher's Set (if any) has been applied -
+// so Launch's Permitted/Inheritable priming reflects the Set it just
+// installed, not whatever the thread had beforehand. Pass no vals to
+// clear a previously configured Ambient raise.
+func (l *Launcher) SetAmbient(vals ...Value) {
+	l.ambient = vals
+}
+
+// Launch locks an OS thread for the duration of the call, applies
+// this Launcher's configured Set (if any, via
+// SetProcWithSeccomp/SetProc) and seccomp.Program (if any), then
+// invokes the callback with payload, returning whatever error it
+// produces. The thread is released (UnlockOSThread) before Launch
+// returns. Because applying a seccomp filter or a reduced Set is
+// generally not reversible for the calling thread, Launch should be
+// used for work that is happy to run out on a disposable thread.
+func (l *Launcher) Launch(payload interface{}) (int, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	switch {
+	case l.set != nil && l.seccomp != nil:
+		if err := l.set.SetProcWithSeccomp(l.seccomp); err != nil {
+			return -1, err
+		}
+	case l.set != nil:
+		if err := l.set.SetProc(); err != nil {
+			return -1, err
+		}
+	case l.seccomp != nil:
+		scwMu.Lock()
+		err := singlesc.setSeccomp(l.seccomp)
+		scwMu.Unlock()
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	if len(l.ambient) != 0 {
+		if err := RaiseAmbient(l.ambient...); err != nil {
+			return -1, err
+		}
+	}
+
+	if l.callback == nil {
+		return 0, nil
+	}
+	if err := l.callback(payload); err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,26 @@
+package cap
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// TestKernelSockFprogLayout guards against a hardcoded, 64-bit-only
+// padding field reappearing between kernelSockFprog.len and .filter:
+// the kernel's "struct sock_fprog" packs filter immediately after
+// len's ordinary pointer-aligned padding, so filter must sit at
+// offsetof(len)+sizeof(len) rounded up to the pointer's own
+// alignment - never a fixed 8 bytes in. This is written to hold on
+// any GOARCH (in particular GOARCH=386 and GOARCH=arm, the 32-bit
+// targets this package installs filters on).
+func TestKernelSockFprogLayout(t *testing.T) {
+	var f kernelSockFprog
+	ptrSize := unsafe.Sizeof(uintptr(0))
+	wantOffset := ((unsafe.Sizeof(f.len) + ptrSize - 1) / ptrSize) * ptrSize
+	if got := unsafe.Offsetof(f.filter); got != wantOffset {
+		t.Fatalf("filter offset = %d, want %d (pointer size %d)", got, wantOffset, ptrSize)
+	}
+	if wantSize := wantOffset + ptrSize; unsafe.Sizeof(f) != wantSize {
+		t.Fatalf("sizeof(kernelSockFprog) = %d, want %d", unsafe.Sizeof(f), wantSize)
+	}
+}
@@ -0,0 +1,116 @@
+package cap
+
+import "strings"
+
+// names holds the canonical, lower case name of every capability
+// Value known at the time this package was built, indexed by that
+// Value. It is kept in sync with include/uapi/linux/capability.h.
+var names = []string{
+	"chown",
+	"dac_override",
+	"dac_read_search",
+	"fowner",
+	"fsetid",
+	"kill",
+	"setgid",
+	"setuid",
+	"setpcap",
+	"linux_immutable",
+	"net_bind_service",
+	"net_broadcast",
+	"net_admin",
+	"net_raw",
+	"ipc_lock",
+	"ipc_owner",
+	"sys_module",
+	"sys_rawio",
+	"sys_chroot",
+	"sys_ptrace",
+	"sys_pacct",
+	"sys_admin",
+	"sys_boot",
+	"sys_nice",
+	"sys_resource",
+	"sys_time",
+	"sys_tty_config",
+	"mknod",
+	"lease",
+	"audit_write",
+	"audit_control",
+	"setfcap",
+	"mac_override",
+	"mac_admin",
+	"syslog",
+	"wake_alarm",
+	"block_suspend",
+	"audit_read",
+	"perfmon",
+	"bpf",
+	"checkpoint_restore",
+}
+
+// NamedCount is the number of capability Values named by this
+// version of the package at build time. MaxBits() prefers the count
+// discovered from the running kernel, and only falls back to this
+// value if that probe fails.
+var NamedCount = uint(len(names))
+
+// String returns the lower case, unprefixed conventional name of a
+// capability Value (e.g. "sys_admin" for CAP_SYS_ADMIN), or
+// "unknown:<n>" if the package does not recognize it.
+func (v Value) String() string {
+	if int(v) < 0 || int(v) >= len(names) {
+		return "unknown:" + itoa(int(v))
+	}
+	return names[v]
+}
+
+// itoa is a tiny decimal formatter so this file does not need to
+// import "strconv" purely for an error path.
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	var buf [20]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + n%10)
+		n /= 10
+	}
+	if neg {
+		i--
+		buf[i] = '-'
+	}
+	return string(buf[i:])
+}
+
+// FromName parses a capability name in any of the forms accepted by
+// libcap's cap_from_name(3): bare ("sys_admin"), upper case
+// ("SYS_ADMIN") or "CAP_"-prefixed in either case ("CAP_SYS_ADMIN").
+// It returns an error identifying the unrecognized name otherwise.
+func FromName(name string) (Value, error) {
+	n := strings.ToLower(name)
+	n = strings.TrimPrefix(n, "cap_")
+	for i, known := range names {
+		if known == n {
+			return Value(i), nil
+		}
+	}
+	return 0, &ErrUnknownName{Name: name}
+}
+
+// ErrUnknownName indicates a capability name that this package's
+// built-in name table does not recognize. The running kernel is not
+// consulted.
+type ErrUnknownName struct {
+	Name string
+}
+
+func (e *ErrUnknownName) Error() string {
+	return "cap: unknown capability name: " + e.Name
+}
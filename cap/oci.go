@@ -0,0 +1,132 @@
+package cap
+
+import "strings"
+
+// ParseCapList converts a list of capability names in the style
+// accepted by OCI runtime configs and container engines like Docker
+// and containerd - bare or "CAP_"-prefixed, in any case, plus the
+// magic name "ALL" expanding to every capability the running kernel
+// implements (per MaxBits) - into the corresponding Values. The
+// first unrecognized name is reported via ErrUnknownName.
+func ParseCapList(names []string) ([]Value, error) {
+	var out []Value
+	for _, n := range names {
+		if strings.EqualFold(n, "all") {
+			max := MaxBits()
+			for v := Value(0); v < max; v++ {
+				out = append(out, v)
+			}
+			continue
+		}
+		v, err := FromName(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// NormalizeCapList re-renders a list of capability names (in any of
+// the forms ParseCapList accepts) into the "CAP_"-prefixed, upper
+// case form - the form most OCI tooling
+// expects back out. "ALL" is expanded, matching ParseCapList.
+func NormalizeCapList(names []string) ([]string, error) {
+	vals, err := ParseCapList(names)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		out[i] = "CAP_" + strings.ToUpper(v.String())
+	}
+	return out, nil
+}
+
+// CapListError reports the capability names from an ApplyCapList
+// call that the running kernel does not implement, distinguishing
+// them from names this package does not recognize at all (which are
+// reported directly as an ErrUnknownName instead).
+type CapListError struct {
+	Unsupported []string
+}
+
+func (e *CapListError) Error() string {
+	return "cap: kernel does not support: " + strings.Join(e.Unsupported, ", ")
+}
+
+// ApplyCapList adjusts Set c to reflect add and drop, lists of
+// capability names in the form ParseCapList accepts. Drops are
+// applied first (removed from Effective, Permitted and Inheritable),
+// then adds (raised in all three), matching the order Docker's
+// daemon applies an OCI spec's capability diffs in. If any named
+// capability is not implemented by the running kernel (its Value is
+// >= MaxBits()), it is skipped and reported via a *CapListError
+// rather than aborting the whole call; all supported names are still
+// applied.
+func (c *Set) ApplyCapList(add, drop []string) error {
+	if c == nil || len(c.flat) == 0 {
+		return ErrBadSet
+	}
+	dropVals, err := ParseCapList(drop)
+	if err != nil {
+		return err
+	}
+	addVals, err := ParseCapList(add)
+	if err != nil {
+		return err
+	}
+
+	var unsupported []string
+	max := MaxBits()
+	apply := func(vals []Value, enable bool) {
+		for _, v := range vals {
+			if v >= max {
+				unsupported = append(unsupported, "CAP_"+strings.ToUpper(v.String()))
+				continue
+			}
+			c.SetFlag(Effective, enable, v)
+			c.SetFlag(Permitted, enable, v)
+			c.SetFlag(Inheritable, enable, v)
+		}
+	}
+	apply(dropVals, false)
+	apply(addVals, true)
+
+	if len(unsupported) != 0 {
+		return &CapListError{Unsupported: unsupported}
+	}
+	return nil
+}
+
+// ApplyCapList replaces the contents of Vector vec with the
+// capability names in list (in the form ParseCapList accepts),
+// clearing any bit not named. It is the IAB counterpart to
+// (*Set).ApplyCapList, intended for the Bounding, Ambient and
+// Inheritable vectors of an OCI runtime spec's
+// linux.capabilities.{bounding,ambient,inheritable} fields - the
+// Permitted and Effective fields of such a spec belong on a *Set and
+// should go through (*Set).ApplyCapList instead.
+func (i *IAB) ApplyCapList(vec Vector, list []string) error {
+	vals, err := ParseCapList(list)
+	if err != nil {
+		return err
+	}
+	max := MaxBits()
+	var unsupported []string
+	var kept []Value
+	for _, v := range vals {
+		if v >= max {
+			unsupported = append(unsupported, "CAP_"+strings.ToUpper(v.String()))
+			continue
+		}
+		kept = append(kept, v)
+	}
+	if err := i.Fill(vec, kept...); err != nil {
+		return err
+	}
+	if len(unsupported) != 0 {
+		return &CapListError{Unsupported: unsupported}
+	}
+	return nil
+}
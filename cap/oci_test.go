@@ -0,0 +1,53 @@
+package cap
+
+import "testing"
+
+// withMaxBits temporarily overrides the kernel-probed capability
+// count so tests can exercise the "running kernel doesn't implement
+// this name" path without depending on the test machine's actual
+// kernel vintage. It calls MaxBits() first so startUp.Do has already
+// run cInit once and will not later overwrite the override.
+func withMaxBits(t *testing.T, n uint) {
+	t.Helper()
+	MaxBits()
+	old := maxValues
+	maxValues = n
+	t.Cleanup(func() { maxValues = old })
+}
+
+// TestApplyCapListMixedAllDoesNotPanic guards against indexing the
+// pre-expansion add/drop slice by the position of a post-expansion
+// Value: mixing "ALL" with an explicit name keeps the input slice
+// short while ParseCapList's output can be much longer (and, on a
+// kernel that does not support every named capability, getting an
+// "unsupported" hit at a position beyond the input slice's length
+// used to panic).
+func TestApplyCapListMixedAllDoesNotPanic(t *testing.T) {
+	withMaxBits(t, 38) // pretend bpf/checkpoint_restore/perfmon aren't implemented.
+
+	c := NewSet()
+	err := c.ApplyCapList([]string{"ALL", "checkpoint_restore"}, nil)
+	cle, ok := err.(*CapListError)
+	if !ok {
+		t.Fatalf("expected *CapListError, got %T: %v", err, err)
+	}
+	if len(cle.Unsupported) != 1 || cle.Unsupported[0] != "CAP_CHECKPOINT_RESTORE" {
+		t.Fatalf("unexpected unsupported list: %v", cle.Unsupported)
+	}
+}
+
+// TestIABApplyCapListMixedAllDoesNotPanic is the IAB counterpart of
+// TestApplyCapListMixedAllDoesNotPanic.
+func TestIABApplyCapListMixedAllDoesNotPanic(t *testing.T) {
+	withMaxBits(t, 38)
+
+	i := NewIAB()
+	err := i.ApplyCapList(Bound, []string{"ALL", "checkpoint_restore"})
+	cle, ok := err.(*CapListError)
+	if !ok {
+		t.Fatalf("expected *CapListError, got %T: %v", err, err)
+	}
+	if len(cle.Unsupported) != 1 || cle.Unsupported[0] != "CAP_CHECKPOINT_RESTORE" {
+		t.Fatalf("unexpected unsupported list: %v", cle.Unsupported)
+	}
+}
@@ -0,0 +1,228 @@
+// Package seccomp provides a minimal, pure Go assembler for Linux
+// seccomp BPF programs. It is a companion to the
+// "kernel.org/pub/linux/libs/security/libcap/cap" package: the two
+// are designed to be used together so a process can drop Capabilities
+// and install a syscall filter as a single atomic step when changing
+// privilege (see (*cap.Set).SetProcWithSeccomp and
+// (*cap.Launcher).SetSeccomp).
+//
+// The instruction encoding follows the classic BPF layout documented
+// in the kernel's Documentation/userspace-api/seccomp_filter.rst: a
+// flat array of "sock_filter" instructions operating over the
+// "struct seccomp_data" presented to the filter for every syscall.
+//
+// This package only builds the instruction array; it performs no
+// system calls itself. Installing a compiled Program is the
+// responsibility of the cap package, which installs it on the
+// calling OS thread only.
+package seccomp // import "kernel.org/pub/linux/libs/security/libcap/cap/seccomp"
+
+import "errors"
+
+// Action identifies the disposition the kernel applies to a syscall
+// that matches (or fails to match) a Rule.
+type Action uint32
+
+// The supported filter actions. These map directly onto the
+// SECCOMP_RET_* values from uapi/linux/seccomp.h.
+const (
+	KillThread Action = 0x00000000
+	Trap       Action = 0x00030000
+	Errno      Action = 0x00050000
+	Allow      Action = 0x7fff0000
+	KillProc   Action = 0x80000000
+)
+
+// sockFilter mirrors the kernel's "struct sock_filter" (uapi
+// linux/filter.h). BPF_LD/BPF_JMP/BPF_RET instructions are encoded
+// into this fixed 8-byte layout.
+type sockFilter struct {
+	code uint16
+	jt   uint8
+	jf   uint8
+	k    uint32
+}
+
+// classic BPF opcodes used to assemble the program. Only the subset
+// needed to inspect "struct seccomp_data" is defined here.
+const (
+	bpfLd  = 0x00
+	bpfJmp = 0x05
+	bpfRet = 0x06
+
+	bpfW   = 0x00
+	bpfAbs = 0x20
+
+	bpfJeq = 0x10
+	bpfK   = 0x00
+)
+
+// Offsets into "struct seccomp_data" (uapi/linux/seccomp.h):
+//
+//	struct seccomp_data {
+//	        int   nr;
+//	        __u32 arch;
+//	        __u64 instruction_pointer;
+//	        __u64 args[6];
+//	};
+const (
+	offNr      = 0
+	offArgBase = 16
+	argWidth   = 8
+)
+
+// argMatch describes a single 32-bit argument comparison, evaluated
+// against the low word of the indicated syscall argument.
+type argMatch struct {
+	index uint
+	value uint32
+}
+
+// rule associates a syscall number with the action taken if it (and
+// an optional argument match) applies.
+type rule struct {
+	nr     uintptr
+	action Action
+	errno  int
+	arg    *argMatch
+}
+
+// Program is a builder for a seccomp BPF filter. Rules are evaluated
+// in the order they were added; the first matching Rule wins. If no
+// Rule matches, the Program's Default action applies.
+type Program struct {
+	rules []rule
+	dflt  Action
+}
+
+// NewProgram returns an empty Program whose default action is
+// KillProc, the same fail-closed default the kernel itself uses when
+// SECCOMP_SET_MODE_FILTER is requested without an explicit default.
+func NewProgram() *Program {
+	return &Program{dflt: KillProc}
+}
+
+// Default overrides the action applied when no Rule matches.
+func (p *Program) Default(action Action) *Program {
+	p.dflt = action
+	return p
+}
+
+// Allow adds a Rule that permits syscall nr to proceed unfiltered.
+func (p *Program) Allow(nr uintptr) *Program {
+	p.rules = append(p.rules, rule{nr: nr, action: Allow})
+	return p
+}
+
+// Errno adds a Rule that fails syscall nr with the supplied errno
+// without letting it reach the kernel's normal implementation.
+func (p *Program) Errno(nr uintptr, errno int) *Program {
+	p.rules = append(p.rules, rule{nr: nr, action: Errno, errno: errno})
+	return p
+}
+
+// TrapOnMatch adds a Rule that raises SIGSYS (via the Trap action)
+// when syscall nr is invoked with its arg'th argument (0-indexed)
+// equal to value. Only the low 32 bits of the argument are compared.
+func (p *Program) TrapOnMatch(nr uintptr, arg uint, value uint32) *Program {
+	p.rules = append(p.rules, rule{
+		nr:     nr,
+		action: Trap,
+		arg:    &argMatch{index: arg, value: value},
+	})
+	return p
+}
+
+// ErrNoRules is returned by Assemble if the Program has no Rules and
+// no kernel would be sensibly restricted by it.
+var ErrNoRules = errors.New("seccomp: program has no rules")
+
+// Assemble compiles the Program into a flat sock_filter array ready
+// to be wrapped in a "struct sock_fprog" and passed to the
+// SECCOMP_SET_MODE_FILTER operation. Callers outside this module
+// normally do not need to call this directly: cap.(*Set).
+// SetProcWithSeccomp and cap.(*Launcher).SetSeccomp do so as part of
+// installing the filter.
+func (p *Program) Assemble() ([]uint16, []uint8, []uint8, []uint32, error) {
+	if len(p.rules) == 0 {
+		return nil, nil, nil, nil, ErrNoRules
+	}
+
+	var prog []sockFilter
+	// Load the syscall number into the accumulator.
+	prog = append(prog, sockFilter{code: bpfLd | bpfW | bpfAbs, k: offNr})
+
+	// Each rule is emitted as a single self-contained, contiguous
+	// block ending in its own BPF_RET: a no-arg rule is
+	// {JEQ nr, RET action}, and an arg-matched rule is
+	// {JEQ nr, LD arg, JEQ arg, RET action, LD nr}, the trailing
+	// LD nr putting the syscall number back in the accumulator for
+	// the next rule when the argument did not match. Because every jt/jf is
+	// a *relative* offset counted from the instruction immediately
+	// after the jump, and every block is emitted back-to-back, each
+	// jump only ever needs to know how many instructions remain in
+	// its own block - it never needs to know the final position of
+	// anything, so there is no patch-up pass.
+	for _, r := range p.rules {
+		retK := uint32(r.action)
+		if r.action == Errno {
+			retK |= uint32(r.errno) & 0xffff
+		}
+		if r.arg == nil {
+			// jt=0: nr matched, fall through to the RET right
+			// below. jf=1: nr didn't match, skip that RET to
+			// reach the next rule (or the final default RET).
+			prog = append(prog,
+				sockFilter{code: bpfJmp | bpfJeq | bpfK, jt: 0, jf: 1, k: uint32(r.nr)},
+				sockFilter{code: bpfRet | bpfK, k: retK},
+			)
+			continue
+		}
+		// jf=4: nr didn't match, skip the remaining LD/JEQ/RET/LD
+		// of this block to reach the next rule.
+		prog = append(prog, sockFilter{code: bpfJmp | bpfJeq | bpfK, jt: 0, jf: 4, k: uint32(r.nr)})
+		prog = append(prog, sockFilter{code: bpfLd | bpfW | bpfAbs, k: uint32(offArgBase + argWidth*r.arg.index)})
+		// jt=0: arg matched, fall through to RET. jf=1: arg
+		// didn't match, skip RET to reload nr for the next rule.
+		prog = append(prog,
+			sockFilter{code: bpfJmp | bpfJeq | bpfK, jt: 0, jf: 1, k: r.arg.value},
+			sockFilter{code: bpfRet | bpfK, k: retK},
+			sockFilter{code: bpfLd | bpfW | bpfAbs, k: offNr},
+		)
+	}
+	prog = append(prog, sockFilter{code: bpfRet | bpfK, k: uint32(p.dflt)})
+
+	codes := make([]uint16, len(prog))
+	jts := make([]uint8, len(prog))
+	jfs := make([]uint8, len(prog))
+	ks := make([]uint32, len(prog))
+	for i, ins := range prog {
+		codes[i], jts[i], jfs[i], ks[i] = ins.code, ins.jt, ins.jf, ins.k
+	}
+	return codes, jts, jfs, ks, nil
+}
+
+// RestrictedAdminProgram returns a curated Program suitable for
+// pairing with dropping CAP_SYS_ADMIN: it forbids mount(2),
+// umount2(2), pivot_root(2) and kexec_load(2) (and kexec_file_load(2))
+// while allowing every other syscall through, mirroring the policy a
+// caller would otherwise need CAP_SYS_ADMIN to enforce via the
+// mount namespace alone. Syscall numbers are those of linux/amd64;
+// callers targeting another architecture should build their own
+// equivalent Program with the Allow/Errno/TrapOnMatch builders.
+func RestrictedAdminProgram() *Program {
+	const (
+		sysMount        = 165
+		sysUmount2      = 166
+		sysPivotRoot    = 155
+		sysKexecLoad    = 246
+		sysKexecFileLoad = 320
+	)
+	return NewProgram().
+		Errno(sysMount, 1).
+		Errno(sysUmount2, 1).
+		Errno(sysPivotRoot, 1).
+		Errno(sysKexecLoad, 1).
+		Errno(sysKexecFileLoad, 1).
+		Default(Allow)
+}
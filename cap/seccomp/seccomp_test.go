@@ -0,0 +1,86 @@
+package seccomp
+
+import "testing"
+
+// TestAssembleDistinctJumps guards against the class of bug where a
+// rule's jt and jf offsets end up equal, making its action fire
+// unconditionally for every syscall instead of only on a match.
+func TestAssembleDistinctJumps(t *testing.T) {
+	p := NewProgram().Errno(1, 1).Errno(2, 1).Errno(3, 1).Default(Allow)
+	codes, jts, jfs, _, err := p.Assemble()
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	for i, code := range codes {
+		if code&0x07 != bpfJmp {
+			continue
+		}
+		if jts[i] == jfs[i] {
+			t.Fatalf("instruction %d: jt == jf == %d, rule action is unconditional", i, jts[i])
+		}
+	}
+}
+
+// TestRestrictedAdminProgramOnlyMatchesListedSyscalls walks the
+// compiled program for every syscall number it names and confirms it
+// resolves to Errno, and that an unrelated syscall number resolves to
+// the Default action instead - catching the case where the first
+// rule's action fired for every syscall.
+func TestRestrictedAdminProgramOnlyMatchesListedSyscalls(t *testing.T) {
+	const (
+		sysMount         = 165
+		sysUmount2       = 166
+		sysPivotRoot     = 155
+		sysKexecLoad     = 246
+		sysKexecFileLoad = 320
+		sysRead          = 0
+	)
+	p := RestrictedAdminProgram()
+	for _, nr := range []uintptr{sysMount, sysUmount2, sysPivotRoot, sysKexecLoad, sysKexecFileLoad} {
+		if run(t, p, nr) != Errno {
+			t.Fatalf("syscall %d: expected Errno action", nr)
+		}
+	}
+	if got := run(t, p, sysRead); got != Allow {
+		t.Fatalf("syscall %d (unrelated): expected Allow, got %#x", sysRead, got)
+	}
+}
+
+// run interprets a compiled Program against a single candidate
+// syscall number, returning the Action it resolves to. It only
+// understands the instruction shapes Assemble itself emits: a
+// leading nr load, followed by JEQ-on-nr blocks, terminated by a
+// default RET.
+func run(t *testing.T, p *Program, nr uintptr) Action {
+	t.Helper()
+	codes, jts, jfs, ks, err := p.Assemble()
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	pc := 0
+	var acc uint32
+	for {
+		if pc >= len(codes) {
+			t.Fatalf("ran off the end of the program")
+		}
+		switch codes[pc] {
+		case bpfLd | bpfW | bpfAbs:
+			if ks[pc] == offNr {
+				acc = uint32(nr)
+			} else {
+				acc = 0 // this test never matches on an argument value.
+			}
+			pc++
+		case bpfJmp | bpfJeq | bpfK:
+			if acc == ks[pc] {
+				pc += 1 + int(jts[pc])
+			} else {
+				pc += 1 + int(jfs[pc])
+			}
+		case bpfRet | bpfK:
+			return Action(ks[pc] &^ 0xffff)
+		default:
+			t.Fatalf("unrecognized instruction %#x at pc=%d", codes[pc], pc)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package cap
+
+// sysSeccomp is the seccomp(2) syscall number for this architecture.
+// See seccomp_syscall_amd64.go for why this package keeps its own
+// table instead of relying on syscall.SYS_SECCOMP.
+const sysSeccomp = 354
@@ -0,0 +1,9 @@
+package cap
+
+// sysSeccomp is the seccomp(2) syscall number for this architecture.
+// The Go standard library's syscall package does not export
+// SYS_SECCOMP for every architecture (notably not for amd64), so this
+// package keeps its own small, build-tag'd table alongside the other
+// architecture-specific constants it already depends on via the
+// syscall package.
+const sysSeccomp = 317
@@ -0,0 +1,13 @@
+//go:build !amd64 && !386 && !arm
+
+package cap
+
+import "syscall"
+
+// sysSeccomp is the seccomp(2) syscall number for this architecture.
+// The Go standard library exports syscall.SYS_SECCOMP on arm64,
+// s390x, riscv64, mips64, mips64le and loong64, so use it directly
+// rather than maintaining our own entry for each of them. It does not
+// export it on mips, mipsle, ppc64 or ppc64le, so this package does
+// not build for those architectures.
+const sysSeccomp = syscall.SYS_SECCOMP